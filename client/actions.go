@@ -13,10 +13,13 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PwzXxm/raft-lite/rpccore"
@@ -38,39 +41,115 @@ type Client struct {
 type ClientCore struct {
 	ActBuilder *sm.TSMActionBuilder
 
-	clientID        string
+	clientID string
+	node     rpccore.Node
+	logger   *logrus.Logger
+
+	// mu guards leaderID, backOffDuration and nl, which used to be safe
+	// to mutate without locking because only the stdin REPL ever called
+	// into ClientCore. Now that the HTTP gateway can call in
+	// concurrently from its own goroutines, every read/write of this
+	// state must go through mu.
+	mu              sync.Mutex
 	leaderID        *rpccore.NodeID
-	nl              []rpccore.NodeID
-	node            rpccore.Node
-	logger          *logrus.Logger
 	backOffDuration int
+	nl              []rpccore.NodeID
+
+	// actBuilderMu serializes access to ActBuilder, whose request-ID
+	// counter is likewise unsafe for concurrent use.
+	actBuilderMu sync.Mutex
+
+	// actionMu serializes the whole build-submit-confirm cycle for a
+	// top-level action (see submitAction). sm.NewTSMLatestRequestQuery
+	// only exposes a single latest-request slot per clientID, not one
+	// per outstanding request, so two actions from this client in
+	// flight at once would race for that slot: the earlier one could
+	// never see its own RequestID and would spuriously time out and
+	// retry an already-committed action. actBuilderMu alone isn't
+	// enough — it only serializes request-ID generation, not the
+	// confirmation polling that follows.
+	actionMu sync.Mutex
+}
+
+// nodeList returns a snapshot of the client's current node list. Safe to
+// call concurrently with setNodeList.
+func (core *ClientCore) nodeList() []rpccore.NodeID {
+	core.mu.Lock()
+	defer core.mu.Unlock()
+	return append([]rpccore.NodeID(nil), core.nl...)
+}
+
+// setNodeList replaces the client's node list, e.g. after a membership
+// change. Safe to call concurrently with nodeList.
+func (core *ClientCore) setNodeList(nl []rpccore.NodeID) {
+	core.mu.Lock()
+	defer core.mu.Unlock()
+	core.nl = nl
+}
+
+// declinedError indicates that the targeted node explicitly rejected an
+// action or query request at the RPC level (e.g. it isn't the leader),
+// as opposed to the cluster being unreachable or leaderless.
+type declinedError struct {
+	node rpccore.NodeID
+}
+
+func (e *declinedError) Error() string {
+	return fmt.Sprintf("Node %v declined the request.", e.node)
+}
+
+// buildAction serializes access to ActBuilder's request-ID counter, which
+// the HTTP gateway and the stdin REPL may now call concurrently.
+func (core *ClientCore) buildAction(f func(b *sm.TSMActionBuilder) sm.TSMAction) sm.TSMAction {
+	core.actBuilderMu.Lock()
+	defer core.actBuilderMu.Unlock()
+	return f(core.ActBuilder)
 }
 
 const (
 	maxBackOffDuration       = 1600 // ms
 	initBackOffDuration      = 20   // ms
 	maxCheckCountBeforeRetry = 6
+	maxStaleQueryAttempts    = 10
 )
 
 const (
-	tcpTimeout        = time.Second
-	cmdQuery          = "query"
-	cmdSet            = "set"
-	cmdIncre          = "increment"
-	cmdMove           = "move"
-	cmdSetLoggerLevel = "loggerLevel"
-	loggerLevelDebug  = "debug"
-	loggerLevelInfo   = "info"
-	loggerLevelWarn   = "warn"
-	loggerLevelError  = "error"
+	tcpTimeout         = time.Second
+	cmdQuery           = "query"
+	cmdSet             = "set"
+	cmdIncre           = "increment"
+	cmdMove            = "move"
+	cmdMember          = "member"
+	cmdMemberAdd       = "add"
+	cmdMemberRemove    = "remove"
+	cmdMemberList      = "list"
+	cmdSnapshot        = "snapshot"
+	cmdSnapshotSave    = "save"
+	cmdSnapshotRestore = "restore"
+	cmdWatch           = "watch"
+	cmdTxn             = "txn"
+	cmdTxnCompare      = "compare"
+	cmdTxnThen         = "then"
+	cmdTxnElse         = "else"
+	cmdTxnCommit       = "commit"
+	cmdTxnExists       = "exists"
+	cmdSetLoggerLevel  = "loggerLevel"
+	loggerLevelDebug   = "debug"
+	loggerLevelInfo    = "info"
+	loggerLevelWarn    = "warn"
+	loggerLevelError   = "error"
 )
 
 // command usage maps
 var usageMp = map[string]string{
-	cmdQuery:          "<key>",
+	cmdQuery:          "<key> [stale|default|linearizable]",
 	cmdSet:            "<key> <value>",
 	cmdIncre:          "<key> <value>",
 	cmdMove:           "<source> <target> <value>",
+	cmdMember:         "<add <nodeID> <addr> | remove <nodeID> | list>",
+	cmdSnapshot:       "<save <file> | restore <file>>",
+	cmdWatch:          "<key>",
+	cmdTxn:            "(enters multi-line mode, terminated by 'commit')",
 	cmdSetLoggerLevel: "<level> (warn, info, debug, error)",
 }
 
@@ -151,11 +230,18 @@ func (c *Client) startReadingCmd() {
 			switch cmd[0] {
 			// query command
 			case cmdQuery:
-				if l != 2 {
+				if l != 2 && l != 3 {
 					err = combineErrorUsage(invalidCommandError, cmd[0])
 					break
 				}
-				res, err := c.core.ExecuteQueryRequest(sm.NewTSMDataQuery(cmd[1]))
+				consistency := ConsistencyDefault
+				if l == 3 {
+					consistency, err = parseConsistency(cmd[2])
+					if err != nil {
+						break
+					}
+				}
+				res, err := c.core.ExecuteQueryRequest(context.Background(), sm.NewTSMDataQuery(cmd[1]), consistency)
 				if err != nil {
 					_, _ = red.Println(err)
 				} else {
@@ -196,9 +282,13 @@ func (c *Client) startReadingCmd() {
 				}
 				switch cmd[0] {
 				case cmdSet:
-					c.executeActionRequestAndPrint(c.core.ActBuilder.TSMActionSetValue(cmd[1], value))
+					c.executeActionRequestAndPrint(func(b *sm.TSMActionBuilder) sm.TSMAction {
+						return b.TSMActionSetValue(cmd[1], value)
+					})
 				case cmdIncre:
-					c.executeActionRequestAndPrint(c.core.ActBuilder.TSMActionIncrValue(cmd[1], value))
+					c.executeActionRequestAndPrint(func(b *sm.TSMActionBuilder) sm.TSMAction {
+						return b.TSMActionIncrValue(cmd[1], value)
+					})
 				}
 			// move command
 			case cmdMove:
@@ -211,7 +301,114 @@ func (c *Client) startReadingCmd() {
 					err = errors.New("value should be an integer")
 					break
 				}
-				c.executeActionRequestAndPrint(c.core.ActBuilder.TSMActionMoveValue(cmd[1], cmd[2], value))
+				c.executeActionRequestAndPrint(func(b *sm.TSMActionBuilder) sm.TSMAction {
+					return b.TSMActionMoveValue(cmd[1], cmd[2], value)
+				})
+			// member command: add, remove, list
+			case cmdMember:
+				if l < 2 {
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+					break
+				}
+				switch cmd[1] {
+				case cmdMemberAdd:
+					if l != 4 {
+						err = combineErrorUsage(invalidCommandError, cmd[0])
+						break
+					}
+					if e := c.MemberAdd(rpccore.NodeID(cmd[2]), cmd[3]); e != nil {
+						_, _ = red.Println(e)
+					} else {
+						_, _ = green.Printf("Node %v added\n", cmd[2])
+					}
+				case cmdMemberRemove:
+					if l != 3 {
+						err = combineErrorUsage(invalidCommandError, cmd[0])
+						break
+					}
+					if e := c.MemberRemove(rpccore.NodeID(cmd[2])); e != nil {
+						_, _ = red.Println(e)
+					} else {
+						_, _ = green.Printf("Node %v removed\n", cmd[2])
+					}
+				case cmdMemberList:
+					if l != 2 {
+						err = combineErrorUsage(invalidCommandError, cmd[0])
+						break
+					}
+					green.Println(c.MemberList())
+				default:
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+				}
+			// snapshot command: save, restore
+			case cmdSnapshot:
+				if l != 3 {
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+					break
+				}
+				switch cmd[1] {
+				case cmdSnapshotSave:
+					if e := c.core.SnapshotSave(cmd[2]); e != nil {
+						_, _ = red.Println(e)
+					} else {
+						_, _ = green.Printf("Snapshot saved to %v\n", cmd[2])
+					}
+				case cmdSnapshotRestore:
+					if e := c.core.SnapshotRestore(cmd[2]); e != nil {
+						_, _ = red.Println(e)
+					} else {
+						_, _ = green.Printf("Snapshot restored from %v\n", cmd[2])
+					}
+				default:
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+				}
+			// watch command: stream change events for a key until interrupted
+			case cmdWatch:
+				if l != 2 {
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+					break
+				}
+				ch := make(chan WatchEvent)
+				stop := make(chan struct{})
+				sigCh := make(chan os.Signal, 1)
+				signal.Notify(sigCh, os.Interrupt)
+				c.core.Watch(cmd[1], ch, stop)
+				green.Printf("Watching key %v, press Ctrl+C to stop\n", cmd[1])
+			watchLoop:
+				for {
+					select {
+					case ev, ok := <-ch:
+						if !ok {
+							break watchLoop
+						}
+						green.Printf("[%v] %v: %v -> %v\n", ev.Index, cmd[1], ev.OldValue, ev.NewValue)
+					case <-sigCh:
+						close(stop)
+						// drain ch until Watch's goroutine observes stop and closes it
+						for range ch {
+						}
+						break watchLoop
+					}
+				}
+				signal.Stop(sigCh)
+			// txn command: multi-op atomic compare-and-swap batch
+			case cmdTxn:
+				if l != 1 {
+					err = combineErrorUsage(invalidCommandError, cmd[0])
+					break
+				}
+				guards, then, els, ok := c.readTxn(scanner, green, red)
+				if !ok {
+					break
+				}
+				results, txnErr := c.core.ExecuteTxnRequest(context.Background(), guards, then, els)
+				if txnErr != nil {
+					_, _ = red.Println(txnErr)
+					break
+				}
+				for i, res := range results {
+					printActionResult(res.Success, fmt.Sprintf("op %d: %v", i, res.Msg))
+				}
 			default:
 				_, _ = red.Fprintln(os.Stderr, invalidCommandError)
 				utils.PrintUsage(usageMp)
@@ -228,9 +425,19 @@ func (c *Client) startReadingCmd() {
 	}
 }
 
-// client executes the action request and prints result messages
-func (c *Client) executeActionRequestAndPrint(act sm.TSMAction) {
-	success, msg := c.core.ExecuteActionRequest(act)
+// client builds an action via build, executes the action request and
+// prints result messages
+func (c *Client) executeActionRequestAndPrint(build func(b *sm.TSMActionBuilder) sm.TSMAction) {
+	success, msg, err := c.core.ExecuteActionRequest(context.Background(), build)
+	if err != nil {
+		msg = err.Error()
+	}
+	printActionResult(success, msg)
+}
+
+// printActionResult prints the (success, message) pair returned by
+// ExecuteActionRequest / ExecuteTxnRequest in green or red accordingly.
+func printActionResult(success bool, msg string) {
 	var ca color.Attribute
 	if success {
 		ca = color.FgGreen
@@ -245,64 +452,101 @@ func combineErrorUsage(e error, cmd string) error {
 	return errors.New(e.Error() + "\nUsage: " + cmd + " " + usageMp[cmd])
 }
 
-func (core *ClientCore) lookForLeader() rpccore.NodeID {
-	// cached, the cache will be cleaned if there is any issue
-	// blocking, keep trying until find a leader
-	for core.leaderID == nil {
+// lookForLeader returns the cached leader, discovering one if necessary.
+// The cache is cleared whenever a request fails. It blocks until ctx is
+// done or a leader is found; callers that want unbounded retries (as the
+// stdin REPL always has) should pass context.Background().
+func (core *ClientCore) lookForLeader(ctx context.Context) (rpccore.NodeID, error) {
+	for {
+		core.mu.Lock()
+		leaderID := core.leaderID
+		core.mu.Unlock()
+		if leaderID != nil {
+			return *leaderID, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
 		// select a client by random
-		pl := core.nl[utils.Random(0, len(core.nl)-1)]
+		nl := core.nodeList()
+		pl := nl[utils.Random(0, len(nl)-1)]
 		var leaderRes LeaderRes
 		err := callRPC(core, pl, RPCMethodLeaderRequest, "", &leaderRes)
 		if err == nil {
 			if leaderRes.HasLeader {
 				core.logger.Infof("Node %v answered with leader = %v", pl,
 					leaderRes.LeaderID)
+				core.mu.Lock()
 				core.leaderID = &leaderRes.LeaderID
+				core.mu.Unlock()
 				resetBackOffDuration(core)
-				return *core.leaderID
+				return leaderRes.LeaderID, nil
 			}
 			err = errors.Errorf("Node %v doesn't know the leader.", pl)
 		}
-		core.logErrAndBackoff("Unable to find leader. ", err)
+		if !core.logErrAndBackoff(ctx, "Unable to find leader. ", err) {
+			return "", ctx.Err()
+		}
 	}
-	return *core.leaderID
 }
 
 // resetBackOffDuration resets the backOffDuration
 func resetBackOffDuration(core *ClientCore) {
+	core.mu.Lock()
+	defer core.mu.Unlock()
 	core.backOffDuration = initBackOffDuration
 }
 
-// logErrAndBackoff takes ClientCore pointer, message string and error value
-func (core *ClientCore) logErrAndBackoff(msg string, err error) {
+// logErrAndBackoff clears the cached leader, logs err, and sleeps the
+// current backoff duration before doubling it. It returns false without
+// sleeping the full duration if ctx is done first, so callers with a
+// deadline (e.g. the HTTP gateway) don't block past it.
+func (core *ClientCore) logErrAndBackoff(ctx context.Context, msg string, err error) bool {
+	core.mu.Lock()
 	core.leaderID = nil
+	d := core.backOffDuration
+	core.mu.Unlock()
+
 	core.logger.Debug(msg, err)
 
-	// this function can only be called when one action failed
-	// thus, only one counter is necessary
-	time.Sleep(time.Duration(core.backOffDuration) * time.Millisecond)
+	select {
+	case <-time.After(time.Duration(d) * time.Millisecond):
+	case <-ctx.Done():
+		return false
+	}
 
+	core.mu.Lock()
 	core.backOffDuration = utils.Min(maxBackOffDuration, core.backOffDuration*2)
+	core.mu.Unlock()
+	return true
 }
 
 // sendActionRequest takes ClientCore and ActionReq structs as arguments,
 // calls action request RPC, and returns error value if occurs
-func (core *ClientCore) sendActionRequest(actReq ActionReq) error {
-	leader := core.lookForLeader()
+func (core *ClientCore) sendActionRequest(ctx context.Context, actReq ActionReq) error {
+	leader, err := core.lookForLeader(ctx)
+	if err != nil {
+		return err
+	}
 	var actionRes ActionRes
-	err := callRPC(core, leader, RPCMethodActionRequest, actReq, &actionRes)
+	err = callRPC(core, leader, RPCMethodActionRequest, actReq, &actionRes)
 	if err == nil && !actionRes.Started {
-		err = errors.Errorf("Node %v declined the request.", leader)
+		return &declinedError{node: leader}
 	}
 	return err
 }
 
 // checkActionRequest takes ClientCore and QueryReq structs as arguments,
 // calls query request RPC and returns a TSMRequestInfo pointer if success
-func (core *ClientCore) checkActionRequest(queryReq QueryReq) (*sm.TSMRequestInfo, error) {
-	leader := core.lookForLeader()
+func (core *ClientCore) checkActionRequest(ctx context.Context, queryReq QueryReq) (*sm.TSMRequestInfo, error) {
+	leader, err := core.lookForLeader(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var queryRes QueryRes
-	err := callRPC(core, leader, RPCMethodQueryRequest, queryReq, &queryRes)
+	err = callRPC(core, leader, RPCMethodQueryRequest, queryReq, &queryRes)
 	if err == nil {
 		if queryRes.Success {
 			if queryRes.QueryErr == nil {
@@ -312,54 +556,158 @@ func (core *ClientCore) checkActionRequest(queryReq QueryReq) (*sm.TSMRequestInf
 			// query success, but there is no related request info
 			return nil, nil
 		}
-		err = errors.Errorf("Node %v decliend the query request.", leader)
+		err = &declinedError{node: leader}
 	}
 	return nil, err
 }
 
-// ExecuteActionRequest takes ClientCore and TSMAction structs as arguments,
-// and returns whether the action is succeed and error value if occurs
-func (core *ClientCore) ExecuteActionRequest(act sm.TSMAction) (bool, string) {
+// confirmAction submits act to the leader and polls
+// sm.NewTSMLatestRequestQuery(core.clientID) until the sm.TSMRequestInfo
+// matching act's own RequestID comes back (confirming it committed), or
+// ctx is done first. ExecuteActionRequest and ExecuteTxnRequest share
+// this send-then-poll loop and differ only in how they interpret the
+// returned info, since a txn's info carries a per-op result vector
+// instead of the single Err a plain action has.
+//
+// If ctx is done before a matching info is seen, it returns (nil, err)
+// where err is a *declinedError if the leader actively rejecting the
+// request was the most recent failure seen, and ctx.Err() otherwise (the
+// cluster was unreachable or leaderless, or the commit was never
+// confirmed) — the HTTP gateway uses this distinction to choose between
+// 409 and 503. Callers that want the
+// original unbounded-retry behaviour (the stdin REPL and every other
+// existing caller) should pass context.Background().
+func (core *ClientCore) confirmAction(ctx context.Context, act sm.TSMAction) (*sm.TSMRequestInfo, error) {
 	actReq := ActionReq{Cmd: act}
 	queryReq := QueryReq{Cmd: sm.NewTSMLatestRequestQuery(core.clientID)}
 	reqID := act.GetRequestID()
+	var lastErr error
+	// giveUp is returned once ctx is done: a leader's rejection is passed
+	// on as is, any other failure is reported as ctx's own error.
+	giveUp := func() (*sm.TSMRequestInfo, error) {
+		if _, ok := lastErr.(*declinedError); ok {
+			return nil, lastErr
+		}
+		return nil, ctx.Err()
+	}
 	for {
-		err := core.sendActionRequest(actReq)
+		err := core.sendActionRequest(ctx, actReq)
 		if err != nil {
-			core.logErrAndBackoff("send action request failed. ", err)
+			lastErr = err
+			if !core.logErrAndBackoff(ctx, "send action request failed. ", err) {
+				return giveUp()
+			}
 			continue
 		}
 		resetBackOffDuration(core)
 
 		for i := 0; i < maxCheckCountBeforeRetry; i++ {
-			info, err := core.checkActionRequest(queryReq)
+			info, err := core.checkActionRequest(ctx, queryReq)
 			if err != nil {
-				core.logErrAndBackoff("check action request failed. ", err)
+				lastErr = err
 			}
 			// RequestInfo exists and RequestID matches
 			if info != nil && info.RequestID == reqID {
 				resetBackOffDuration(core)
-				if info.Err != nil {
-					return false, *info.Err
-				}
-				return true, "action success"
+				return info, nil
 			}
 			if err == nil {
-				core.logErrAndBackoff("info is nil or wrong request ID", err)
+				lastErr = errors.New("info is nil or wrong request ID")
+			}
+			if !core.logErrAndBackoff(ctx, "check action request failed. ", lastErr) {
+				return giveUp()
 			}
 		}
 	}
 }
 
-// ExecuteQueryRequest takes ClientCore and TSMQuery structs as arguments,
-// and returns data from the query response and error value if occurs
-func (core *ClientCore) ExecuteQueryRequest(query sm.TSMQuery) (interface{}, error) {
-	queryReq := QueryReq{Cmd: query}
+// submitAction holds actionMu for the whole build-submit-confirm cycle:
+// it builds act by calling build with ActBuilder, then submits it and
+// waits for confirmAction to see it commit. Building the action (which
+// consumes the next request ID) and confirming it (which polls the
+// single latest-request slot for core.clientID) have to happen as one
+// atomic step from this ClientCore's point of view, or a second action
+// built and submitted while the first is still being confirmed would
+// overwrite that slot first — see actionMu's doc comment.
+func (core *ClientCore) submitAction(ctx context.Context, build func(b *sm.TSMActionBuilder) sm.TSMAction) (*sm.TSMRequestInfo, error) {
+	core.actionMu.Lock()
+	defer core.actionMu.Unlock()
+	act := core.buildAction(build)
+	return core.confirmAction(ctx, act)
+}
+
+// ExecuteActionRequest builds an action via build and waits for it to
+// commit, respecting ctx's deadline/cancellation via confirmAction. On
+// success it returns (true, "action success", nil). On an
+// application-level decline it returns (false, <reason>, nil). If ctx is
+// done before either happens, it returns (false, "", err); see
+// confirmAction for what err is in that case.
+func (core *ClientCore) ExecuteActionRequest(ctx context.Context, build func(b *sm.TSMActionBuilder) sm.TSMAction) (bool, string, error) {
+	info, err := core.submitAction(ctx, build)
+	if err != nil {
+		return false, "", err
+	}
+	if info.Err != nil {
+		return false, *info.Err, nil
+	}
+	return true, "action success", nil
+}
+
+// ExecuteQueryRequest takes ClientCore, a TSMQuery and a consistency
+// level, and returns data from the query response and error value if
+// occurs, respecting ctx's deadline/cancellation the same way
+// ExecuteActionRequest does. ConsistencyStale is sent to randomly picked
+// known nodes without leader discovery, giving up after
+// maxStaleQueryAttempts failures; ConsistencyDefault and
+// ConsistencyLinearizable are both sent to the leader, with the level
+// carried in QueryReq.Consistency (see consistency.go for how nodes treat
+// that field).
+func (core *ClientCore) ExecuteQueryRequest(ctx context.Context, query sm.TSMQuery, consistency Consistency) (interface{}, error) {
+	queryReq := QueryReq{Cmd: query, Consistency: consistency}
+
+	if consistency == ConsistencyStale {
+		// No node currently honours ConsistencyStale (see consistency.go):
+		// every node still declines it like any other non-leader query,
+		// so this only "works" by chance when the random node picked
+		// happens to be the leader. Until that server-side support
+		// exists, bound the attempts and warn loudly instead of retrying
+		// forever and presenting declines as if a follower could have
+		// answered.
+		var lastErr error
+		for attempt := 0; attempt < maxStaleQueryAttempts; attempt++ {
+			nl := core.nodeList()
+			target := nl[utils.Random(0, len(nl)-1)]
+			var queryRes QueryRes
+			err := callRPC(core, target, RPCMethodQueryRequest, queryReq, &queryRes)
+			if err == nil {
+				if queryRes.Success {
+					resetBackOffDuration(core)
+					if queryRes.QueryErr == nil {
+						return queryRes.Data, nil
+					}
+					return nil, errors.New(*queryRes.QueryErr)
+				}
+				err = &declinedError{node: target}
+			}
+			lastErr = err
+			core.logger.Warnf("Stale query declined by %v; ConsistencyStale has no server-side "+
+				"support yet, so this is a random guess at the leader, not a follower read. %v", target, err)
+			if !core.logErrAndBackoff(ctx, "Stale query failed. ", err) {
+				return nil, err
+			}
+		}
+		return nil, errors.Errorf("stale query did not reach a node willing to answer it after %d attempts: %v",
+			maxStaleQueryAttempts, lastErr)
+	}
+
 	for {
-		leader := core.lookForLeader()
+		leader, err := core.lookForLeader(ctx)
+		if err != nil {
+			return nil, err
+		}
 		var queryRes QueryRes
 		// call query request RPC
-		err := callRPC(core, leader, RPCMethodQueryRequest, queryReq, &queryRes)
+		err = callRPC(core, leader, RPCMethodQueryRequest, queryReq, &queryRes)
 		if err == nil {
 			if queryRes.Success {
 				resetBackOffDuration(core)
@@ -369,11 +717,10 @@ func (core *ClientCore) ExecuteQueryRequest(query sm.TSMQuery) (interface{}, err
 				// query success, but query error exists
 				return nil, errors.New(*queryRes.QueryErr)
 			}
-			err = errors.Errorf("Node %v decliend the query request.", leader)
+			err = &declinedError{node: leader}
 		}
-		if err != nil {
-			core.logErrAndBackoff("Request query failed. ", err)
-			continue
+		if !core.logErrAndBackoff(ctx, "Request query failed. ", err) {
+			return nil, err
 		}
 	}
 }
@@ -21,6 +21,10 @@ import (
 type clientConfig struct {
 	NodeAddrMap map[rpccore.NodeID]string
 	ClientID    string
+	// HTTPListenAddr, if non-empty, starts an HTTP/JSON gateway exposing
+	// the CLI's query, set, increment and move operations (e.g.
+	// "localhost:8080").
+	HTTPListenAddr string
 }
 
 // StartClientFromFile starts Client from given file
@@ -45,6 +49,18 @@ func StartClientFromFile(filepath string) error {
 		return err
 	}
 
+	if config.HTTPListenAddr != "" {
+		hs := newHTTPServer(&c.core, config.HTTPListenAddr)
+		go func() {
+			if err := hs.Start(); err != nil {
+				c.core.logger.Errorf("HTTP gateway stopped: %v", err)
+			}
+		}()
+		defer func() {
+			_ = hs.Shutdown()
+		}()
+	}
+
 	c.startReadingCmd()
 	c.net.Shutdown()
 	return nil
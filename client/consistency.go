@@ -0,0 +1,68 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import "github.com/pkg/errors"
+
+// Consistency selects how strongly a query result is guaranteed to
+// reflect the latest committed state, trading off latency for recency.
+//
+// This file, and the Consistency field added to QueryReq, are only the
+// client side of this feature. ConsistencyDefault is unaffected (it's
+// the zero value and today's existing behaviour), but ConsistencyStale
+// requires a node to answer queries without going through the leader,
+// and ConsistencyLinearizable requires the leader to run a ReadIndex
+// round before answering — both are server-side query-handling changes
+// that belong in the raft core / state machine packages, which this
+// slice of the tree doesn't include. Until that lands, a node receiving
+// a QueryReq with a non-default Consistency will ignore the field and
+// answer as it does today.
+type Consistency int
+
+const (
+	// ConsistencyDefault is served by the leader from its local state
+	// machine. This is today's behaviour, and the zero value so that
+	// requests which don't set Consistency keep working unchanged.
+	ConsistencyDefault Consistency = iota
+	// ConsistencyStale is served by any node straight from its local
+	// state machine, skipping leader discovery entirely. Cheapest, but
+	// may return data that lags behind the latest commit.
+	ConsistencyStale
+	// ConsistencyLinearizable is served by the leader only after a
+	// ReadIndex round confirms it is still the leader of a quorum,
+	// guaranteeing the read observes every previously committed write.
+	ConsistencyLinearizable
+)
+
+const (
+	consistencyFlagStale        = "stale"
+	consistencyFlagDefault      = "default"
+	consistencyFlagLinearizable = "linearizable"
+)
+
+// parseConsistency parses a consistency level name, as given by the query
+// command's optional third argument or the HTTP gateway's consistency
+// parameter, treating an empty string as ConsistencyDefault. The query
+// command takes the level as a plain positional argument; it has no
+// --consistency flag.
+func parseConsistency(s string) (Consistency, error) {
+	switch s {
+	case consistencyFlagStale:
+		return ConsistencyStale, nil
+	case consistencyFlagDefault, "":
+		return ConsistencyDefault, nil
+	case consistencyFlagLinearizable:
+		return ConsistencyLinearizable, nil
+	default:
+		return ConsistencyDefault, errors.Errorf("unknown consistency level %q", s)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import "testing"
+
+func TestParseConsistency(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    Consistency
+		wantErr bool
+	}{
+		{"empty defaults", "", ConsistencyDefault, false},
+		{"default flag", "default", ConsistencyDefault, false},
+		{"stale", "stale", ConsistencyStale, false},
+		{"linearizable", "linearizable", ConsistencyLinearizable, false},
+		{"unknown", "bogus", ConsistencyDefault, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseConsistency(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseConsistency(%q): expected an error, got nil", tc.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseConsistency(%q): unexpected error: %v", tc.in, err)
+			}
+			if got != tc.want {
+				t.Fatalf("parseConsistency(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
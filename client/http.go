@@ -0,0 +1,213 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PwzXxm/raft-lite/sm"
+)
+
+// httpRequestTimeout bounds how long a single HTTP request waits for the
+// cluster to have a leader / commit a request before replying 503.
+const httpRequestTimeout = 3 * time.Second
+
+// httpServer exposes the query, set, increment and move operations of the
+// interactive CLI, plus /leader, /nodes and /health, over a plain
+// HTTP/JSON API; the member, snapshot, watch and txn commands are CLI
+// only. This lets tooling that isn't
+// linked against rpccore (curl, dashboards, load generators) talk to the
+// cluster directly.
+type httpServer struct {
+	core *ClientCore
+	srv  *http.Server
+}
+
+// newHTTPServer returns an httpServer that serves on addr and delegates
+// every request to core.
+func newHTTPServer(core *ClientCore, addr string) *httpServer {
+	s := &httpServer{core: core}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/query", s.handleQuery)
+	mux.HandleFunc("/set", s.handleSet)
+	mux.HandleFunc("/increment", s.handleIncrement)
+	mux.HandleFunc("/move", s.handleMove)
+	mux.HandleFunc("/leader", s.handleLeader)
+	mux.HandleFunc("/nodes", s.handleNodes)
+	mux.HandleFunc("/health", s.handleHealth)
+
+	s.srv = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Start serves HTTP requests until Shutdown is called.
+func (s *httpServer) Start() error {
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server.
+func (s *httpServer) Shutdown() error {
+	return s.srv.Shutdown(context.Background())
+}
+
+type setReq struct {
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+}
+
+type moveReq struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Value  int    `json:"value"`
+}
+
+func (s *httpServer) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeHTTPError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeHTTPError(w, http.StatusBadRequest, "missing key")
+		return
+	}
+	consistency, err := parseConsistency(r.URL.Query().Get("consistency"))
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), httpRequestTimeout)
+	defer cancel()
+
+	data, err := s.core.ExecuteQueryRequest(ctx, sm.NewTSMDataQuery(key), consistency)
+	if err != nil {
+		writeCoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"value": data})
+}
+
+func (s *httpServer) handleSet(w http.ResponseWriter, r *http.Request) {
+	var req setReq
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	s.executeAction(w, r, func(b *sm.TSMActionBuilder) sm.TSMAction {
+		return b.TSMActionSetValue(req.Key, req.Value)
+	})
+}
+
+func (s *httpServer) handleIncrement(w http.ResponseWriter, r *http.Request) {
+	var req setReq
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	s.executeAction(w, r, func(b *sm.TSMActionBuilder) sm.TSMAction {
+		return b.TSMActionIncrValue(req.Key, req.Value)
+	})
+}
+
+func (s *httpServer) handleMove(w http.ResponseWriter, r *http.Request) {
+	var req moveReq
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	s.executeAction(w, r, func(b *sm.TSMActionBuilder) sm.TSMAction {
+		return b.TSMActionMoveValue(req.Source, req.Target, req.Value)
+	})
+}
+
+// executeAction builds an action via build, submits it, and translates
+// the result into a JSON response, giving up once r's context (bounded
+// by httpRequestTimeout) expires.
+func (s *httpServer) executeAction(w http.ResponseWriter, r *http.Request, build func(b *sm.TSMActionBuilder) sm.TSMAction) {
+	ctx, cancel := context.WithTimeout(r.Context(), httpRequestTimeout)
+	defer cancel()
+
+	success, msg, err := s.core.ExecuteActionRequest(ctx, build)
+	if err != nil {
+		writeCoreError(w, err)
+		return
+	}
+	if success {
+		writeJSON(w, http.StatusOK, map[string]string{"status": msg})
+	} else {
+		writeHTTPError(w, http.StatusConflict, msg)
+	}
+}
+
+func (s *httpServer) handleLeader(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), httpRequestTimeout)
+	defer cancel()
+
+	id, err := s.core.lookForLeader(ctx)
+	if err != nil {
+		writeCoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"leader": string(id)})
+}
+
+// writeCoreError maps an error returned by a ClientCore call to an HTTP
+// status: a request whose deadline passed without a leader rejecting it
+// (the cluster is unreachable or leaderless, or the commit was never
+// confirmed) is 503, while every other error, such as a *declinedError
+// from a leader that actively rejected the request or a query's own
+// error, is 409.
+func writeCoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeHTTPError(w, http.StatusServiceUnavailable, "no leader available, try again later")
+		return
+	}
+	writeHTTPError(w, http.StatusConflict, err.Error())
+}
+
+func (s *httpServer) handleNodes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": s.core.nodeList()})
+}
+
+func (s *httpServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// decodeJSONBody decodes r's JSON body into v, writing a 400 response and
+// returning false on failure.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeHTTPError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
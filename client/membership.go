@@ -0,0 +1,118 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/PwzXxm/raft-lite/rpccore"
+	"github.com/pkg/errors"
+)
+
+// RPCMethodMembershipChange is the RPC method used to ask the leader to add
+// or remove a single node from the cluster.
+//
+// This file only adds the client side of the RPC: the method name, the
+// request/response shapes, and the `member` command that sends them. The
+// leader-side handler (append the config-change entry, apply it, and
+// replicate the resulting configuration to followers) lives in the raft
+// core and state machine packages and is not part of this change; until
+// that lands, RPCMethodMembershipChange has no registered handler and
+// every call to it fails with an RPC error rather than changing anything.
+const RPCMethodMembershipChange = "MembershipChange"
+
+// MembershipOp identifies the kind of membership change being requested.
+type MembershipOp int
+
+const (
+	// MembershipOpAdd adds a new node to the cluster.
+	MembershipOpAdd MembershipOp = iota
+	// MembershipOpRemove removes an existing node from the cluster.
+	MembershipOpRemove
+)
+
+// MembershipChangeReq is sent to the leader to add or remove a single node.
+// Only one membership change may be pending at a time: the leader appends
+// it as a config-change log entry and the new configuration takes effect
+// once that entry is applied, following the single-server joint-consensus
+// approach from the Raft dissertation.
+type MembershipChangeReq struct {
+	Op     MembershipOp
+	NodeID rpccore.NodeID
+	Addr   string
+}
+
+// MembershipChangeRes reports whether the change was accepted and, on
+// success, carries the resulting membership so the caller can refresh its
+// routing table without restarting.
+type MembershipChangeRes struct {
+	Success     bool
+	Err         *string
+	NodeAddrMap map[rpccore.NodeID]string
+}
+
+// memberChange submits req to the leader and returns the resulting
+// membership on success.
+func (core *ClientCore) memberChange(req MembershipChangeReq) (map[rpccore.NodeID]string, error) {
+	leader, err := core.lookForLeader(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	var res MembershipChangeRes
+	err = callRPC(core, leader, RPCMethodMembershipChange, req, &res)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Success {
+		msg := "request declined"
+		if res.Err != nil {
+			msg = *res.Err
+		}
+		return nil, errors.Errorf("Node %v declined the membership change: %v", leader, msg)
+	}
+	return res.NodeAddrMap, nil
+}
+
+// MemberAdd adds nodeID (reachable at addr) to the cluster and refreshes
+// the client's own routing table so subsequent requests can reach it.
+func (c *Client) MemberAdd(nodeID rpccore.NodeID, addr string) error {
+	return c.applyMembership(MembershipChangeReq{Op: MembershipOpAdd, NodeID: nodeID, Addr: addr})
+}
+
+// MemberRemove removes nodeID from the cluster.
+func (c *Client) MemberRemove(nodeID rpccore.NodeID) error {
+	return c.applyMembership(MembershipChangeReq{Op: MembershipOpRemove, NodeID: nodeID})
+}
+
+// MemberList returns the node IDs the client currently knows about.
+func (c *Client) MemberList() []rpccore.NodeID {
+	return c.core.nodeList()
+}
+
+// applyMembership submits req to the leader and, on success, updates the
+// client's local node list and network routing table to match the new
+// membership, so a restart isn't needed to see the change.
+func (c *Client) applyMembership(req MembershipChangeReq) error {
+	nodeAddrMap, err := c.core.memberChange(req)
+	if err != nil {
+		return err
+	}
+
+	nl := make([]rpccore.NodeID, 0, len(nodeAddrMap))
+	for nodeID, addr := range nodeAddrMap {
+		// registering an already-known node is a harmless no-op
+		_ = c.net.NewRemoteNode(nodeID, addr)
+		nl = append(nl, nodeID)
+	}
+	c.core.setNodeList(nl)
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// RPCMethodSnapshotFetch and RPCMethodSnapshotInstall let an operator pull
+// the leader's current state-machine snapshot down to a local file, and
+// later push it back up to (re-)seed a cluster, without touching any
+// node's on-disk state directly.
+//
+// Only the client side is implemented here: the wire format, the
+// save/restore commands, and these two RPC method names. The leader-side
+// handlers (serialize the current sm.TSMState for Fetch; validate,
+// truncate the log to LastIncludedIndex, and replace the state machine
+// for Install) live in the raft core / state machine packages and aren't
+// part of this change, so neither RPC has a registered handler yet.
+const (
+	RPCMethodSnapshotFetch   = "SnapshotFetch"
+	RPCMethodSnapshotInstall = "SnapshotInstall"
+)
+
+const (
+	snapshotMagic   uint32 = 0x52465453 // "RFTS"
+	snapshotVersion uint32 = 1
+)
+
+// snapshotHeader is written uncompressed at the start of a saved snapshot
+// file, followed by a gzip-compressed body holding the serialized
+// sm.TSMState.
+type snapshotHeader struct {
+	Magic             uint32
+	Version           uint32
+	LastIncludedTerm  uint64
+	LastIncludedIndex uint64
+	CRC32             uint32
+}
+
+// SnapshotFetchRes carries the leader's current snapshot.
+type SnapshotFetchRes struct {
+	LastIncludedTerm  uint64
+	LastIncludedIndex uint64
+	Data              []byte // serialized sm.TSMState
+}
+
+// SnapshotInstallReq uploads a snapshot for the leader to install: it
+// truncates its log to LastIncludedIndex, replaces its state machine, and
+// replicates an InstallSnapshot-style entry to followers.
+type SnapshotInstallReq struct {
+	LastIncludedTerm  uint64
+	LastIncludedIndex uint64
+	Data              []byte
+	CRC32             uint32
+}
+
+// SnapshotInstallRes reports whether the uploaded snapshot was installed.
+type SnapshotInstallRes struct {
+	Success bool
+	Err     *string
+}
+
+// SnapshotSave fetches the leader's current snapshot and writes it to
+// filepath as a versioned header followed by a gzip-compressed body.
+func (core *ClientCore) SnapshotSave(filepath string) error {
+	leader, err := core.lookForLeader(context.Background())
+	if err != nil {
+		return err
+	}
+	var res SnapshotFetchRes
+	if err := callRPC(core, leader, RPCMethodSnapshotFetch, "", &res); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath)
+	if err != nil {
+		return errors.Wrap(err, "unable to create snapshot file")
+	}
+	defer f.Close()
+
+	header := snapshotHeader{
+		Magic:             snapshotMagic,
+		Version:           snapshotVersion,
+		LastIncludedTerm:  res.LastIncludedTerm,
+		LastIncludedIndex: res.LastIncludedIndex,
+		CRC32:             crc32.ChecksumIEEE(res.Data),
+	}
+	if err := binary.Write(f, binary.BigEndian, header); err != nil {
+		return errors.Wrap(err, "unable to write snapshot header")
+	}
+
+	gw := gzip.NewWriter(f)
+	if _, err := gw.Write(res.Data); err != nil {
+		return errors.Wrap(err, "unable to write snapshot body")
+	}
+	return gw.Close()
+}
+
+// SnapshotRestore reads a snapshot previously written by SnapshotSave,
+// validates its checksum, and uploads it to the leader to install.
+func (core *ClientCore) SnapshotRestore(filepath string) error {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return errors.Wrap(err, "unable to open snapshot file")
+	}
+	defer f.Close()
+
+	var header snapshotHeader
+	if err := binary.Read(f, binary.BigEndian, &header); err != nil {
+		return errors.Wrap(err, "unable to read snapshot header")
+	}
+	if header.Magic != snapshotMagic {
+		return errors.New("not a valid raft-lite snapshot file")
+	}
+	if header.Version != snapshotVersion {
+		return errors.Errorf("unsupported snapshot version %v", header.Version)
+	}
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrap(err, "unable to read snapshot body")
+	}
+	defer gr.Close()
+	data, err := ioutil.ReadAll(gr)
+	if err != nil {
+		return errors.Wrap(err, "unable to read snapshot body")
+	}
+	if crc32.ChecksumIEEE(data) != header.CRC32 {
+		return errors.New("snapshot checksum mismatch")
+	}
+
+	leader, err := core.lookForLeader(context.Background())
+	if err != nil {
+		return err
+	}
+	req := SnapshotInstallReq{
+		LastIncludedTerm:  header.LastIncludedTerm,
+		LastIncludedIndex: header.LastIncludedIndex,
+		Data:              data,
+		CRC32:             header.CRC32,
+	}
+	var res SnapshotInstallRes
+	if err := callRPC(core, leader, RPCMethodSnapshotInstall, req, &res); err != nil {
+		return err
+	}
+	if !res.Success {
+		msg := "request declined"
+		if res.Err != nil {
+			msg = *res.Err
+		}
+		return errors.Errorf("Node %v declined the snapshot install: %v", leader, msg)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/PwzXxm/raft-lite/sm"
+	"github.com/fatih/color"
+)
+
+// TxnOpResult is the outcome of a single then/else action applied as part
+// of a committed txn, mirroring the (bool, string) pair
+// ExecuteActionRequest returns for a standalone action.
+type TxnOpResult struct {
+	Success bool
+	Msg     string
+}
+
+// ExecuteTxnRequest submits guards plus a then/else list of actions to the
+// leader as a single Raft log entry, applied atomically by the state
+// machine: if every guard holds, then is applied, otherwise els is. The
+// move command is still submitted as its own action, not as a txn. On
+// success it returns one TxnOpResult per op in the branch that ran, in
+// order, so the caller can tell exactly which ops succeeded — committing
+// the txn doesn't mean every op inside it did (e.g. a conditional
+// increment that would overflow). sm.TSMRequestInfo carries these in a
+// TxnResults field that's only populated for txn actions; every other
+// action kind leaves it nil and is handled by plain ExecuteActionRequest.
+// guards and TxnGuard itself live in sm, not client: TSMActionTxn needs
+// to accept them, and sm can't import client (client already imports
+// sm) to do so. Building the top-level txn action and confirming it run
+// under the same lock as any other action (see submitAction). ctx is
+// forwarded to confirmAction; pass context.Background() for the REPL's
+// unbounded-retry behaviour.
+func (core *ClientCore) ExecuteTxnRequest(ctx context.Context, guards []sm.TxnGuard, then []sm.TSMAction, els []sm.TSMAction) ([]TxnOpResult, error) {
+	info, err := core.submitAction(ctx, func(b *sm.TSMActionBuilder) sm.TSMAction {
+		return b.TSMActionTxn(guards, then, els)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]TxnOpResult, len(info.TxnResults))
+	for i, r := range info.TxnResults {
+		results[i] = TxnOpResult{Success: r.Success, Msg: r.Msg}
+	}
+	return results, nil
+}
+
+// readTxn reads a multi-line txn block from scanner, terminated by a line
+// containing just "commit". Grammar:
+//
+//	compare <key> == <value>
+//	compare <key> exists
+//	then
+//	  set|increment <key> <value>
+//	  move <source> <target> <value>
+//	else
+//	  ... (same action grammar as then)
+//	commit
+func (c *Client) readTxn(scanner *bufio.Scanner, green, red *color.Color) ([]sm.TxnGuard, []sm.TSMAction, []sm.TSMAction, bool) {
+	var guards []sm.TxnGuard
+	var then, els []sm.TSMAction
+	inBranch := false
+	cur := &then
+
+	green.Println("Entering txn mode, terminate with 'commit'")
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case cmdTxnCompare:
+			if inBranch {
+				_, _ = red.Println("compare is only valid before 'then'")
+				return nil, nil, nil, false
+			}
+			if len(fields) >= 3 && fields[2] == cmdTxnExists {
+				guards = append(guards, sm.TxnGuard{Key: fields[1], Op: sm.TxnGuardExists})
+				continue
+			}
+			if len(fields) != 4 || fields[2] != "==" {
+				_, _ = red.Println("usage: compare <key> == <value> | compare <key> exists")
+				return nil, nil, nil, false
+			}
+			value, e := strconv.Atoi(fields[3])
+			if e != nil {
+				_, _ = red.Println("value should be an integer")
+				return nil, nil, nil, false
+			}
+			guards = append(guards, sm.TxnGuard{Key: fields[1], Op: sm.TxnGuardEqual, Value: value})
+		case cmdTxnThen:
+			inBranch = true
+			cur = &then
+		case cmdTxnElse:
+			inBranch = true
+			cur = &els
+		case cmdTxnCommit:
+			return guards, then, els, true
+		case cmdSet, cmdIncre:
+			if !inBranch || len(fields) != 3 {
+				_, _ = red.Println("usage: " + fields[0] + " " + usageMp[fields[0]])
+				return nil, nil, nil, false
+			}
+			value, e := strconv.Atoi(fields[2])
+			if e != nil {
+				_, _ = red.Println("value should be an integer")
+				return nil, nil, nil, false
+			}
+			if fields[0] == cmdSet {
+				*cur = append(*cur, c.core.buildAction(func(b *sm.TSMActionBuilder) sm.TSMAction {
+					return b.TSMActionSetValue(fields[1], value)
+				}))
+			} else {
+				*cur = append(*cur, c.core.buildAction(func(b *sm.TSMActionBuilder) sm.TSMAction {
+					return b.TSMActionIncrValue(fields[1], value)
+				}))
+			}
+		case cmdMove:
+			if !inBranch || len(fields) != 4 {
+				_, _ = red.Println("usage: move " + usageMp[cmdMove])
+				return nil, nil, nil, false
+			}
+			value, e := strconv.Atoi(fields[3])
+			if e != nil {
+				_, _ = red.Println("value should be an integer")
+				return nil, nil, nil, false
+			}
+			*cur = append(*cur, c.core.buildAction(func(b *sm.TSMActionBuilder) sm.TSMAction {
+				return b.TSMActionMoveValue(fields[1], fields[2], value)
+			}))
+		default:
+			_, _ = red.Println("unknown txn statement: " + fields[0])
+			return nil, nil, nil, false
+		}
+	}
+	return nil, nil, nil, false
+}
@@ -0,0 +1,144 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"bufio"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/PwzXxm/raft-lite/sm"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestClient returns a Client whose core is wired up enough to build
+// actions (readTxn's set/increment/move branches call core.buildAction),
+// but with no real network node: readTxn never sends an RPC itself.
+func newTestClient() *Client {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	core := NewClientCore("test-client", nil, nil, logger)
+	return &Client{core: core}
+}
+
+func readTxnLines(t *testing.T, c *Client, lines ...string) ([]sm.TxnGuard, bool) {
+	t.Helper()
+	scanner := bufio.NewScanner(strings.NewReader(strings.Join(lines, "\n")))
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	guards, _, _, ok := c.readTxn(scanner, green, red)
+	return guards, ok
+}
+
+func TestReadTxnGuards(t *testing.T) {
+	c := newTestClient()
+	scanner := bufio.NewScanner(strings.NewReader(strings.Join([]string{
+		"compare a == 1",
+		"compare b exists",
+		"commit",
+	}, "\n")))
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	guards, then, els, ok := c.readTxn(scanner, green, red)
+	if !ok {
+		t.Fatalf("readTxn failed unexpectedly")
+	}
+	if len(then) != 0 || len(els) != 0 {
+		t.Fatalf("expected no actions without a then/else block, got then=%v els=%v", then, els)
+	}
+	want := []sm.TxnGuard{
+		{Key: "a", Op: sm.TxnGuardEqual, Value: 1},
+		{Key: "b", Op: sm.TxnGuardExists},
+	}
+	if len(guards) != len(want) {
+		t.Fatalf("got %d guards, want %d", len(guards), len(want))
+	}
+	for i := range want {
+		if guards[i] != want[i] {
+			t.Fatalf("guard %d = %+v, want %+v", i, guards[i], want[i])
+		}
+	}
+}
+
+func TestReadTxnThenElseActions(t *testing.T) {
+	c := newTestClient()
+	scanner := bufio.NewScanner(strings.NewReader(strings.Join([]string{
+		"compare a == 1",
+		"then",
+		"set a 2",
+		"increment b 3",
+		"else",
+		"move a b 1",
+		"commit",
+	}, "\n")))
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	guards, then, els, ok := c.readTxn(scanner, green, red)
+	if !ok {
+		t.Fatalf("readTxn failed unexpectedly")
+	}
+	if len(guards) != 1 {
+		t.Fatalf("got %d guards, want 1", len(guards))
+	}
+	if len(then) != 2 {
+		t.Fatalf("got %d then actions, want 2", len(then))
+	}
+	if len(els) != 1 {
+		t.Fatalf("got %d else actions, want 1", len(els))
+	}
+}
+
+func TestReadTxnCompareAfterThenFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "then", "compare a == 1", "commit"); ok {
+		t.Fatalf("expected readTxn to fail when compare follows then")
+	}
+}
+
+func TestReadTxnBadGuardValueFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "compare a == notanumber", "commit"); ok {
+		t.Fatalf("expected readTxn to fail on a non-integer guard value")
+	}
+}
+
+func TestReadTxnBadGuardUsageFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "compare a", "commit"); ok {
+		t.Fatalf("expected readTxn to fail on a malformed compare statement")
+	}
+}
+
+func TestReadTxnActionOutsideBranchFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "set a 1", "commit"); ok {
+		t.Fatalf("expected readTxn to fail when an action appears before then/else")
+	}
+}
+
+func TestReadTxnUnknownStatementFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "bogus a 1", "commit"); ok {
+		t.Fatalf("expected readTxn to fail on an unrecognised statement")
+	}
+}
+
+func TestReadTxnNoCommitFails(t *testing.T) {
+	c := newTestClient()
+	if _, ok := readTxnLines(t, c, "compare a == 1"); ok {
+		t.Fatalf("expected readTxn to fail when the input ends without 'commit'")
+	}
+}
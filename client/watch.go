@@ -0,0 +1,99 @@
+/*
+ * Project: raft-lite
+ * ---------------------
+ * Authors:
+ *   Minjian Chen 813534
+ *   Shijie Liu   813277
+ *   Weizhi Xu    752454
+ *   Wenqing Xue  813044
+ *   Zijun Chen   813190
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// RPCMethodWatch is a long-poll RPC: the leader (or a follower forwarding
+// via ReadIndex) blocks until a committed TSMAction mutates Key at an
+// index greater than LastIndex, then returns that event, or returns with
+// HasEvent false after its own internal timeout so the client can retry.
+//
+// This file only implements the client side of the long poll: the wire
+// format and the watch command's reconnect loop. Answering it requires a
+// subscriber registry in the state machine's apply loop (to notice a
+// matching mutation and wake the blocked RPC) that this change does not
+// add, so RPCMethodWatch has no registered handler yet and every poll
+// will fail with an RPC error rather than ever seeing HasEvent true.
+const RPCMethodWatch = "Watch"
+
+// WatchReq asks to be notified of the next change to Key after LastIndex.
+type WatchReq struct {
+	Key       string
+	LastIndex uint64
+}
+
+// WatchEvent describes a single committed mutation of a watched key.
+type WatchEvent struct {
+	Index    uint64
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// WatchRes is the long-poll response: either the next event for the
+// watched key, or HasEvent false if the server's internal timeout elapsed
+// with no matching change.
+type WatchRes struct {
+	Success  bool
+	Err      *string
+	HasEvent bool
+	Event    WatchEvent
+}
+
+// Watch streams change events for key to ch until stop is closed, closing
+// ch itself before returning so a caller ranging over ch always sees the
+// stream end. It reconnects with the last seen index on error or leader
+// change, so no committed event is missed across failovers.
+func (core *ClientCore) Watch(key string, ch chan<- WatchEvent, stop <-chan struct{}) {
+	go func() {
+		defer close(ch)
+		var lastIndex uint64
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			var res WatchRes
+			leader, err := core.lookForLeader(context.Background())
+			if err == nil {
+				err = callRPC(core, leader, RPCMethodWatch, WatchReq{Key: key, LastIndex: lastIndex}, &res)
+			}
+			if err == nil && !res.Success {
+				msg := "request declined"
+				if res.Err != nil {
+					msg = *res.Err
+				}
+				err = errors.Errorf("Node %v declined the watch request: %v", leader, msg)
+			}
+			if err != nil {
+				core.logErrAndBackoff(context.Background(), "watch request failed. ", err)
+				continue
+			}
+			resetBackOffDuration(core)
+
+			if res.HasEvent {
+				lastIndex = res.Event.Index
+				select {
+				case ch <- res.Event:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+}